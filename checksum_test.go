@@ -0,0 +1,31 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyChecksumsTreatsMissingRowAsNoBaseline(t *testing.T) {
+	// 历史版本的 (version, dirty) 单行概要表升级而来时，早期 version 没有独立的历史行，
+	// Driver.QueryChecksum 会返回 sql.ErrNoRows，应当与 stored == "" 一样被当作没有基准跳过，
+	// 而不是让 Run 直接报错
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `checksum` FROM schema_migrations")).
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	m := &migrate{db: db, driver: &mysqlDriver{}, schemaTable: "schema_migrations", checksumMode: ChecksumModeStrict}
+	err = m.verifyChecksums(context.Background(), []string{"1.sql"}, 1, map[string]method{})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
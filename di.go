@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	txType      = reflect.TypeOf((*sql.Tx)(nil))
+)
+
+// checkMethodFormat 校验 go 迁移方法的签名：第一个参数必须是 context.Context，
+// 返回值必须是单个 error，其余参数按类型从 container 中解析；*sql.Tx 不要求预先
+// 注册到 container，由 GoHandler 在方法运行于事务内时自动注入
+func checkMethodFormat(mh *method, container map[reflect.Type]reflect.Value) error {
+	if mh.value.Kind() != reflect.Func {
+		return ErrParamIsNotFunc
+	}
+
+	funcType := mh.value.Type()
+	if funcType.NumIn() < 1 || funcType.NumOut() != 1 || funcType.Out(0) != errorType {
+		return fmt.Errorf(errFuncFormatNotCorrectFormat, mh.name)
+	}
+	if funcType.In(0) != contextType {
+		return fmt.Errorf(errFuncFormatNotCorrectFormat, mh.name)
+	}
+
+	var unresolved []string
+	for i := 1; i < funcType.NumIn(); i++ {
+		paramType := funcType.In(i)
+		if paramType == txType {
+			continue
+		}
+		if _, ok := container[paramType]; !ok {
+			unresolved = append(unresolved, paramType.String())
+		}
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf(errFuncParamUnresolvedFormat, mh.name, strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+// resolveParams 按方法签名构建调用参数，ctx 和 tx 之外的参数从 container 中解析；
+// tx 可能为 nil（方法未声明 *sql.Tx 参数，或当前驱动不支持事务）
+func resolveParams(funcType reflect.Type, ctx context.Context, tx *sql.Tx, container map[reflect.Type]reflect.Value) []reflect.Value {
+	args := make([]reflect.Value, funcType.NumIn())
+	args[0] = reflect.ValueOf(ctx)
+	for i := 1; i < funcType.NumIn(); i++ {
+		paramType := funcType.In(i)
+		if paramType == txType {
+			args[i] = reflect.ValueOf(tx)
+			continue
+		}
+		args[i] = container[paramType]
+	}
+	return args
+}
+
+// methodNeedsTx 判断方法签名中是否声明了 *sql.Tx 参数
+func methodNeedsTx(funcType reflect.Type) bool {
+	for i := 1; i < funcType.NumIn(); i++ {
+		if funcType.In(i) == txType {
+			return true
+		}
+	}
+	return false
+}
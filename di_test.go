@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type depA struct {
+	val string
+}
+
+func TestCheckMethodFormat(t *testing.T) {
+	container := map[reflect.Type]reflect.Value{
+		reflect.TypeOf(&depA{}): reflect.ValueOf(&depA{val: "x"}),
+	}
+
+	notFunc := method{name: "NotFunc", value: reflect.ValueOf(1)}
+	assert.Equal(t, ErrParamIsNotFunc, checkMethodFormat(&notFunc, container))
+
+	wrongFirstParam := method{name: "WrongFirstParam", value: reflect.ValueOf(func(s string) error { return nil })}
+	err := checkMethodFormat(&wrongFirstParam, container)
+	assert.EqualError(t, err, "func format WrongFirstParam not correct, should be func(ctx context.Context, ...) error")
+
+	wrongReturn := method{name: "WrongReturn", value: reflect.ValueOf(func(ctx context.Context) {})}
+	err = checkMethodFormat(&wrongReturn, container)
+	assert.EqualError(t, err, "func format WrongReturn not correct, should be func(ctx context.Context, ...) error")
+
+	unresolved := method{name: "Unresolved", value: reflect.ValueOf(func(ctx context.Context, d *depA, s string) error { return nil })}
+	err = checkMethodFormat(&unresolved, container)
+	assert.EqualError(t, err, "func Unresolved has unresolvable param types: string, register them via Migrate.Provide first")
+
+	ok := method{name: "OK", value: reflect.ValueOf(func(ctx context.Context, tx *sql.Tx, d *depA) error { return nil })}
+	assert.NoError(t, checkMethodFormat(&ok, container))
+}
+
+func TestResolveParams(t *testing.T) {
+	dep := &depA{val: "x"}
+	container := map[reflect.Type]reflect.Value{
+		reflect.TypeOf(dep): reflect.ValueOf(dep),
+	}
+
+	fn := func(ctx context.Context, tx *sql.Tx, d *depA) error { return nil }
+	funcType := reflect.TypeOf(fn)
+	ctx := context.Background()
+	var tx *sql.Tx
+
+	args := resolveParams(funcType, ctx, tx, container)
+	assert.Len(t, args, 3)
+	assert.Equal(t, ctx, args[0].Interface())
+	assert.True(t, args[1].IsNil())
+	assert.Same(t, dep, args[2].Interface().(*depA))
+}
+
+func TestMethodNeedsTx(t *testing.T) {
+	withTx := reflect.TypeOf(func(ctx context.Context, tx *sql.Tx) error { return nil })
+	assert.True(t, methodNeedsTx(withTx))
+
+	withoutTx := reflect.TypeOf(func(ctx context.Context) error { return nil })
+	assert.False(t, methodNeedsTx(withoutTx))
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Driver 屏蔽不同数据库在概要表结构、标识符引用、事务能力与批量执行上的差异，
+// 使 migrate 可以在 MySQL 之外的数据库（Postgres、SQLite、ClickHouse、Cassandra 等）上运行。
+//
+// CreateSchemaTable 之外的概要表读写（插入历史记录、读取当前记录、按 version 查校验和等）
+// 同样交给 Driver 实现：id 的生成方式、"当前记录"如何定位、UPDATE 语义在各数据库之间
+// 差异很大（例如 Cassandra 没有自增主键，ClickHouse 的 UPDATE 是异步 mutation），
+// 不能假设统一的 SQL 写法；mysqlDriver/postgresDriver/sqliteDriver 共用 driver.go 中
+// 基于自增 id 的通用实现，cqlDriver/clickhouseDriver 各自实现符合自身能力的版本。
+type Driver interface {
+	// Name 返回驱动名称，供日志与 detectDriver 使用
+	Name() string
+	// QuoteIdent 返回该数据库对标识符的引用写法，例如 MySQL 的反引号、Postgres 的双引号
+	QuoteIdent(ident string) string
+	// Placeholder 返回第 position 个（从 1 开始）参数占位符，例如 MySQL 的 "?"、Postgres 的 "$1"
+	Placeholder(position int) string
+	// CreateSchemaTable 创建概要表（若不存在）；mysqlDriver 额外会把历史版本遗留的
+	// (version, dirty) 两列概要表升级为当前形态，详见 upgradeMySQLSchemaTable
+	CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error
+	// InsertDefaultSchemaRecord 概要表为空时插入初始记录（version=0, direction=up, dirty=false）
+	InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error
+	// QuerySchemaRecord 读取概要表当前记录，即最近一次写入的历史行
+	QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (version int, direction Direction, dirty bool, checksum string, appliedAt time.Time, err error)
+	// InsertSchemaHistory 追加一条历史记录
+	InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error
+	// QueryChecksum 读取某个 version 最新一次 up 历史记录的校验和；没有对应记录时
+	// 返回 sql.ErrNoRows，由调用方决定是否视为"没有基准"
+	QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (checksum string, err error)
+	// QueryStepRecord 读取某个 version 最新一次 up 历史记录的校验和与执行时间；
+	// 没有对应记录时返回 sql.ErrNoRows
+	QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (checksum string, appliedAt time.Time, err error)
+	// UpdateChecksum 供 Repair 重写某个 version 的 up 历史记录的校验和，之后 QueryChecksum
+	// 读到的即为新值；通用实现与 clickhouseDriver 会改写该 version 的全部 up 记录，
+	// cqlDriver 只改写最新一条
+	UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error
+	// SupportsTx 标识该数据库是否支持事务，Cassandra 等返回 false
+	SupportsTx() bool
+	// SplitStatements 将一个迁移文件体拆分为可逐条执行的语句
+	SplitStatements(content string) ([]string, error)
+	// Exec 执行一条语句；当 SupportsTx() 返回 false 时 tx 恒为 nil，此时应直接使用 db 执行
+	Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error
+	// LockSchema 获取概要表级别的锁，避免多实例并发迁移；不支持锁的数据库可返回空操作
+	LockSchema(ctx context.Context, db *sql.DB, table string) (unlock func(context.Context) error, err error)
+}
+
+// driverNames 将 sql.DB 底层驱动的类型名映射到对应的 Driver，供 NewMigrate 自动识别使用
+var driverNames = map[string]func() Driver{
+	"*mysql.MySQLDriver":     func() Driver { return &mysqlDriver{} },
+	"*pq.Driver":             func() Driver { return &postgresDriver{} },
+	"*stdlib.Driver":         func() Driver { return &postgresDriver{} },
+	"*sqlite3.SQLiteDriver":  func() Driver { return &sqliteDriver{} },
+	"*clickhouse.clickhouse": func() Driver { return &clickhouseDriver{} },
+	"*cql.cqlDriver":         func() Driver { return &cqlDriver{} },
+}
+
+// detectDriver 根据 db.Driver() 的具体类型猜测对应的 Driver 实现，未命中时回退到 mysqlDriver
+func detectDriver(db *sql.DB) Driver {
+	name := fmt.Sprintf("%T", db.Driver())
+	if newDriver, ok := driverNames[name]; ok {
+		return newDriver()
+	}
+	return &mysqlDriver{}
+}
+
+// WithDriver 显式指定 Driver，跳过 detectDriver 的自动识别
+func WithDriver(driver Driver) Option {
+	return func(m *migrate) {
+		m.driver = driver
+	}
+}
+
+// 以下是基于自增 id 主键、标准 UPDATE/WHERE 语义的通用概要表读写实现，
+// 供 mysqlDriver/postgresDriver/sqliteDriver 复用；cqlDriver 和 clickhouseDriver
+// 不具备这些前提（没有自增主键、UPDATE 语义不同），各自实现见对应文件。
+
+func genericInsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, driver Driver, table string) error {
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (0, 'up', %s)",
+		table, driver.QuoteIdent("version"), driver.QuoteIdent("direction"), driver.QuoteIdent("dirty"), driver.Placeholder(1))
+	_, err := db.ExecContext(ctx, query, false)
+	return err
+}
+
+func genericQuerySchemaRecord(ctx context.Context, db *sql.DB, driver Driver, table string) (version int, direction Direction, dirty bool, checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT %s, %s, %s, %s, %s FROM %s ORDER BY %s DESC LIMIT 1",
+		driver.QuoteIdent("version"), driver.QuoteIdent("direction"), driver.QuoteIdent("dirty"),
+		driver.QuoteIdent("checksum"), driver.QuoteIdent("applied_at"), table, driver.QuoteIdent("id"))
+	err = db.QueryRowContext(ctx, query).Scan(&version, &direction, &dirty, &checksum, &appliedAt)
+	return
+}
+
+func genericInsertSchemaHistory(ctx context.Context, db *sql.DB, driver Driver, table string, version int, direction Direction, dirty bool, checksum string) error {
+	query := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (%s, %s, %s, %s)",
+		table, driver.QuoteIdent("version"), driver.QuoteIdent("direction"), driver.QuoteIdent("dirty"), driver.QuoteIdent("checksum"),
+		driver.Placeholder(1), driver.Placeholder(2), driver.Placeholder(3), driver.Placeholder(4))
+	_, err := db.ExecContext(ctx, query, version, direction, dirty, checksum)
+	return err
+}
+
+func genericQueryChecksum(ctx context.Context, db *sql.DB, driver Driver, table string, version int) (string, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s AND %s = 'up' ORDER BY %s DESC LIMIT 1",
+		driver.QuoteIdent("checksum"), table, driver.QuoteIdent("version"), driver.Placeholder(1),
+		driver.QuoteIdent("direction"), driver.QuoteIdent("id"))
+	var checksum string
+	err := db.QueryRowContext(ctx, query, version).Scan(&checksum)
+	return checksum, err
+}
+
+func genericQueryStepRecord(ctx context.Context, db *sql.DB, driver Driver, table string, version int) (checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s = %s AND %s = 'up' ORDER BY %s DESC LIMIT 1",
+		driver.QuoteIdent("checksum"), driver.QuoteIdent("applied_at"), table,
+		driver.QuoteIdent("version"), driver.Placeholder(1), driver.QuoteIdent("direction"), driver.QuoteIdent("id"))
+	err = db.QueryRowContext(ctx, query, version).Scan(&checksum, &appliedAt)
+	return
+}
+
+func genericUpdateChecksum(ctx context.Context, db *sql.DB, driver Driver, table string, version int, checksum string) error {
+	query := fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s AND %s = 'up'",
+		table, driver.QuoteIdent("checksum"), driver.Placeholder(1), driver.QuoteIdent("version"), driver.Placeholder(2), driver.QuoteIdent("direction"))
+	_, err := db.ExecContext(ctx, query, checksum, version)
+	return err
+}
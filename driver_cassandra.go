@@ -0,0 +1,101 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	// cqlCreateSchemaTableFormat 没有自增主键可用：bucket 是固定为 0 的分区键（概要表
+	// 本身很小，不需要多分区），id 是写入时刻的 timeuuid，靠 CLUSTERING ORDER BY id DESC
+	// 让“最新一条记录”始终是分区内第一行，对应 mysqlDriver 里自增 id 承担的排序作用
+	cqlCreateSchemaTableFormat = "CREATE TABLE IF NOT EXISTS %s (bucket int, id timeuuid, version int, direction text, dirty boolean, checksum text, applied_at timestamp, PRIMARY KEY (bucket, id)) WITH CLUSTERING ORDER BY (id DESC);"
+
+	cqlSchemaBucket = 0
+)
+
+// cqlDriver 针对 Cassandra（通过 gocql 的 database/sql 适配驱动注册），CQL 没有跨语句事务，
+// 所以 SupportsTx 恒为 false，每条拆分出的语句都单独执行 —— 与 mattes/migrate 生态中
+// 的 Cassandra 驱动一致
+type cqlDriver struct{}
+
+func (d *cqlDriver) Name() string {
+	return "cql"
+}
+
+func (d *cqlDriver) QuoteIdent(ident string) string {
+	return ident
+}
+
+func (d *cqlDriver) Placeholder(int) string {
+	return "?"
+}
+
+func (d *cqlDriver) CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(cqlCreateSchemaTableFormat, table))
+	return err
+}
+
+func (d *cqlDriver) InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error {
+	query := fmt.Sprintf("INSERT INTO %s (bucket, id, version, direction, dirty, checksum, applied_at) VALUES (%d, now(), 0, 'up', false, '', toTimestamp(now()))", table, cqlSchemaBucket)
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *cqlDriver) QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (version int, direction Direction, dirty bool, checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT version, direction, dirty, checksum, applied_at FROM %s WHERE bucket = %d LIMIT 1", table, cqlSchemaBucket)
+	err = db.QueryRowContext(ctx, query).Scan(&version, &direction, &dirty, &checksum, &appliedAt)
+	return
+}
+
+func (d *cqlDriver) InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error {
+	query := fmt.Sprintf("INSERT INTO %s (bucket, id, version, direction, dirty, checksum, applied_at) VALUES (%d, now(), ?, ?, ?, ?, toTimestamp(now()))", table, cqlSchemaBucket)
+	_, err := db.ExecContext(ctx, query, version, direction, dirty, checksum)
+	return err
+}
+
+func (d *cqlDriver) QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (string, error) {
+	// version/direction 不是分区键或聚簇键的一部分，CQL 要求显式 ALLOW FILTERING 才能按它们过滤
+	query := fmt.Sprintf("SELECT checksum FROM %s WHERE bucket = %d AND version = ? AND direction = 'up' LIMIT 1 ALLOW FILTERING", table, cqlSchemaBucket)
+	var checksum string
+	err := db.QueryRowContext(ctx, query, version).Scan(&checksum)
+	return checksum, err
+}
+
+func (d *cqlDriver) QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT checksum, applied_at FROM %s WHERE bucket = %d AND version = ? AND direction = 'up' LIMIT 1 ALLOW FILTERING", table, cqlSchemaBucket)
+	err = db.QueryRowContext(ctx, query, version).Scan(&checksum, &appliedAt)
+	return
+}
+
+func (d *cqlDriver) UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error {
+	// CQL 的 UPDATE 只能按主键定位行，version/direction 不在主键里，所以先查出目标行的
+	// 聚簇键 id，再用 (bucket, id) 定位更新
+	findQuery := fmt.Sprintf("SELECT id FROM %s WHERE bucket = %d AND version = ? AND direction = 'up' LIMIT 1 ALLOW FILTERING", table, cqlSchemaBucket)
+	var id string
+	if err := db.QueryRowContext(ctx, findQuery, version).Scan(&id); err != nil {
+		return err
+	}
+	updateQuery := fmt.Sprintf("UPDATE %s SET checksum = ? WHERE bucket = %d AND id = ?", table, cqlSchemaBucket)
+	_, err := db.ExecContext(ctx, updateQuery, checksum, id)
+	return err
+}
+
+func (d *cqlDriver) SupportsTx() bool {
+	return false
+}
+
+func (d *cqlDriver) SplitStatements(content string) ([]string, error) {
+	return splitSQLStatements(content)
+}
+
+func (d *cqlDriver) Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error {
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *cqlDriver) LockSchema(ctx context.Context, db *sql.DB, table string) (func(context.Context) error, error) {
+	return func(context.Context) error { return nil }, nil
+}
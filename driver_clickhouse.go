@@ -0,0 +1,92 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	// clickhouseCreateSchemaTableFormat 没有自增主键：UInt64 的 id 默认值恒为 0，无法用来
+	// 区分同一 version 的多条历史行。改用 generateUUIDv4() 生成 id，并把 applied_at 换成
+	// 高精度的 DateTime64(6)，使 ORDER BY applied_at DESC 在并发写入下也能稳定区分出最新一行
+	clickhouseCreateSchemaTableFormat = "CREATE TABLE IF NOT EXISTS %s (`id` UUID DEFAULT generateUUIDv4(), `version` Int32, `direction` String, `dirty` UInt8, `checksum` String, `applied_at` DateTime64(6) DEFAULT now64(6)) ENGINE = MergeTree() ORDER BY applied_at;"
+)
+
+// clickhouseDriver 针对 ClickHouse，该引擎不支持事务，因此 SupportsTx 恒为 false，
+// 每条语句都直接通过 db 执行
+type clickhouseDriver struct{}
+
+func (d *clickhouseDriver) Name() string {
+	return "clickhouse"
+}
+
+func (d *clickhouseDriver) QuoteIdent(ident string) string {
+	return "`" + ident + "`"
+}
+
+func (d *clickhouseDriver) Placeholder(int) string {
+	return "?"
+}
+
+func (d *clickhouseDriver) CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(clickhouseCreateSchemaTableFormat, table))
+	return err
+}
+
+func (d *clickhouseDriver) InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error {
+	query := fmt.Sprintf("INSERT INTO %s (version, direction, dirty) VALUES (0, 'up', 0)", table)
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *clickhouseDriver) QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (version int, direction Direction, dirty bool, checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT version, direction, dirty, checksum, applied_at FROM %s ORDER BY applied_at DESC LIMIT 1", table)
+	err = db.QueryRowContext(ctx, query).Scan(&version, &direction, &dirty, &checksum, &appliedAt)
+	return
+}
+
+func (d *clickhouseDriver) InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error {
+	query := fmt.Sprintf("INSERT INTO %s (version, direction, dirty, checksum) VALUES (?, ?, ?, ?)", table)
+	_, err := db.ExecContext(ctx, query, version, direction, dirty, checksum)
+	return err
+}
+
+func (d *clickhouseDriver) QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (string, error) {
+	query := fmt.Sprintf("SELECT checksum FROM %s WHERE version = ? AND direction = 'up' ORDER BY applied_at DESC LIMIT 1", table)
+	var checksum string
+	err := db.QueryRowContext(ctx, query, version).Scan(&checksum)
+	return checksum, err
+}
+
+func (d *clickhouseDriver) QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (checksum string, appliedAt time.Time, err error) {
+	query := fmt.Sprintf("SELECT checksum, applied_at FROM %s WHERE version = ? AND direction = 'up' ORDER BY applied_at DESC LIMIT 1", table)
+	err = db.QueryRowContext(ctx, query, version).Scan(&checksum, &appliedAt)
+	return
+}
+
+func (d *clickhouseDriver) UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error {
+	// MergeTree 引擎不支持行级 UPDATE ... WHERE，只能通过 ALTER TABLE ... UPDATE 发起
+	// 异步 mutation
+	query := fmt.Sprintf("ALTER TABLE %s UPDATE checksum = ? WHERE version = ? AND direction = 'up'", table)
+	_, err := db.ExecContext(ctx, query, checksum, version)
+	return err
+}
+
+func (d *clickhouseDriver) SupportsTx() bool {
+	return false
+}
+
+func (d *clickhouseDriver) SplitStatements(content string) ([]string, error) {
+	return splitSQLStatements(content)
+}
+
+func (d *clickhouseDriver) Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error {
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *clickhouseDriver) LockSchema(ctx context.Context, db *sql.DB, table string) (func(context.Context) error, error) {
+	return func(context.Context) error { return nil }, nil
+}
@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	mysqlCreateSchemaTableFormat = "CREATE TABLE IF NOT EXISTS %s (`id` int NOT NULL AUTO_INCREMENT, `version` int NOT NULL DEFAULT 0, `direction` varchar(8) NOT NULL DEFAULT 'up', `dirty` tinyint(1) NOT NULL DEFAULT 0, `checksum` varchar(64) NOT NULL DEFAULT '', `applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (`id`)) ENGINE=InnoDB;"
+
+	mysqlLockQuery   = "SELECT GET_LOCK(?, 10)"
+	mysqlUnlockQuery = "SELECT RELEASE_LOCK(?)"
+
+	mysqlExistingColumnsQuery = "SELECT COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+
+	errMySQLLockNotAcquiredFormat = "failed to acquire schema lock %s, GET_LOCK returned %v"
+)
+
+// mysqlLegacySchemaColumns 按 ALTER TABLE 所需顺序列出 (version, dirty) 这一历史概要表形态
+// 缺失的列及其补齐语句。`id` 必须排在最前，因为历史表没有主键，需要靠它补上 AUTO_INCREMENT 主键
+var mysqlLegacySchemaColumns = []struct {
+	name  string
+	alter string
+}{
+	{"id", "ADD COLUMN `id` int NOT NULL AUTO_INCREMENT FIRST, ADD PRIMARY KEY (`id`)"},
+	{"direction", "ADD COLUMN `direction` varchar(8) NOT NULL DEFAULT 'up'"},
+	{"checksum", "ADD COLUMN `checksum` varchar(64) NOT NULL DEFAULT ''"},
+	{"applied_at", "ADD COLUMN `applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP"},
+}
+
+// mysqlDriver 是默认驱动，保持与历史版本一致的行为
+type mysqlDriver struct{}
+
+func (d *mysqlDriver) Name() string {
+	return "mysql"
+}
+
+func (d *mysqlDriver) QuoteIdent(ident string) string {
+	return "`" + ident + "`"
+}
+
+func (d *mysqlDriver) Placeholder(int) string {
+	return "?"
+}
+
+func (d *mysqlDriver) CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(mysqlCreateSchemaTableFormat, table)); err != nil {
+		return err
+	}
+	// CREATE TABLE IF NOT EXISTS 对已存在的旧版 (version, dirty) 概要表是 no-op，
+	// 需要显式把缺失的 id/direction/checksum/applied_at 列补齐，否则后续读写历史记录会报错
+	return upgradeMySQLSchemaTable(ctx, db, table)
+}
+
+// upgradeMySQLSchemaTable 检测概要表是否还停留在历史版本的 (version, dirty) 两列形态，
+// 按需补齐新增列，使其升级为当前的历史记录形态
+func upgradeMySQLSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	existing, err := mysqlExistingColumns(ctx, db, table)
+	if err != nil {
+		return err
+	}
+	for _, col := range mysqlLegacySchemaColumns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s %s", table, col.alter)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mysqlExistingColumns(ctx context.Context, db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, mysqlExistingColumnsQuery, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+	return existing, rows.Err()
+}
+
+func (d *mysqlDriver) InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error {
+	return genericInsertDefaultSchemaRecord(ctx, db, d, table)
+}
+
+func (d *mysqlDriver) QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (int, Direction, bool, string, time.Time, error) {
+	return genericQuerySchemaRecord(ctx, db, d, table)
+}
+
+func (d *mysqlDriver) InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error {
+	return genericInsertSchemaHistory(ctx, db, d, table, version, direction, dirty, checksum)
+}
+
+func (d *mysqlDriver) QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (string, error) {
+	return genericQueryChecksum(ctx, db, d, table, version)
+}
+
+func (d *mysqlDriver) QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (string, time.Time, error) {
+	return genericQueryStepRecord(ctx, db, d, table, version)
+}
+
+func (d *mysqlDriver) UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error {
+	return genericUpdateChecksum(ctx, db, d, table, version, checksum)
+}
+
+func (d *mysqlDriver) SupportsTx() bool {
+	return true
+}
+
+func (d *mysqlDriver) SplitStatements(content string) ([]string, error) {
+	return splitSQLStatements(content)
+}
+
+func (d *mysqlDriver) Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error {
+	if tx != nil {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *mysqlDriver) LockSchema(ctx context.Context, db *sql.DB, table string) (func(context.Context) error, error) {
+	lockName := "migrate:" + table
+	// GET_LOCK 本身不会因为锁被占用而报错，而是返回 0（超时未获取到）或 NULL（出错），
+	// 必须读取返回值并显式判断，否则并发迁移会被静默放行
+	var acquired sql.NullInt64
+	if err := db.QueryRowContext(ctx, mysqlLockQuery, lockName).Scan(&acquired); err != nil {
+		return nil, err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return nil, fmt.Errorf(errMySQLLockNotAcquiredFormat, lockName, acquired)
+	}
+	return func(unlockCtx context.Context) error {
+		_, err := db.ExecContext(unlockCtx, mysqlUnlockQuery, lockName)
+		return err
+	}, nil
+}
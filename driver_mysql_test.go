@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMySQLDriverCreateSchemaTableUpgradesLegacyShape(t *testing.T) {
+	// 模拟历史版本遗留的 (version, dirty) 两列概要表：CREATE TABLE IF NOT EXISTS 为 no-op，
+	// 之后应依次为缺失的 id/direction/checksum/applied_at 列执行 ALTER TABLE
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COLUMN_NAME FROM information_schema.COLUMNS")).
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("version").AddRow("dirty"))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE schema_migrations ADD COLUMN `id` int NOT NULL AUTO_INCREMENT FIRST, ADD PRIMARY KEY (`id`)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE schema_migrations ADD COLUMN `direction`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE schema_migrations ADD COLUMN `checksum`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("ALTER TABLE schema_migrations ADD COLUMN `applied_at`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	driver := &mysqlDriver{}
+	err = driver.CreateSchemaTable(context.Background(), db, "schema_migrations")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLDriverLockSchemaErrorsWhenNotAcquired(t *testing.T) {
+	// GET_LOCK 返回 0 表示等待超时、未获取到锁，而不是报错，LockSchema 必须读取返回值
+	// 并把这种情况当作错误，否则会放行并发迁移
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, 10)")).
+		WithArgs("migrate:schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK(?, 10)"}).AddRow(0))
+
+	driver := &mysqlDriver{}
+	unlock, err := driver.LockSchema(context.Background(), db, "schema_migrations")
+	assert.Nil(t, unlock)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLDriverLockSchemaSucceeds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, 10)")).
+		WithArgs("migrate:schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"GET_LOCK(?, 10)"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).
+		WithArgs("migrate:schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	driver := &mysqlDriver{}
+	unlock, err := driver.LockSchema(context.Background(), db, "schema_migrations")
+	assert.NoError(t, err)
+	assert.NoError(t, unlock(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLDriverCreateSchemaTableSkipsUpToDateShape(t *testing.T) {
+	// 新部署或已升级的概要表不应再触发任何 ALTER TABLE
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COLUMN_NAME FROM information_schema.COLUMNS")).
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).
+			AddRow("id").AddRow("version").AddRow("direction").
+			AddRow("dirty").AddRow("checksum").AddRow("applied_at"))
+
+	driver := &mysqlDriver{}
+	err = driver.CreateSchemaTable(context.Background(), db, "schema_migrations")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,86 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	postgresCreateSchemaTableFormat = `CREATE TABLE IF NOT EXISTS %s ("id" bigserial PRIMARY KEY, "version" int NOT NULL DEFAULT 0, "direction" varchar(8) NOT NULL DEFAULT 'up', "dirty" boolean NOT NULL DEFAULT false, "checksum" text NOT NULL DEFAULT '', "applied_at" timestamptz NOT NULL DEFAULT now());`
+
+	postgresLockQuery   = "SELECT pg_advisory_lock(hashtext($1))"
+	postgresUnlockQuery = "SELECT pg_advisory_unlock(hashtext($1))"
+)
+
+// postgresDriver 针对 Postgres，使用双引号标识符、$N 占位符与 pg_advisory_lock
+type postgresDriver struct{}
+
+func (d *postgresDriver) Name() string {
+	return "postgres"
+}
+
+func (d *postgresDriver) QuoteIdent(ident string) string {
+	return `"` + ident + `"`
+}
+
+func (d *postgresDriver) Placeholder(position int) string {
+	return fmt.Sprintf("$%d", position)
+}
+
+func (d *postgresDriver) CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(postgresCreateSchemaTableFormat, table))
+	return err
+}
+
+func (d *postgresDriver) InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error {
+	return genericInsertDefaultSchemaRecord(ctx, db, d, table)
+}
+
+func (d *postgresDriver) QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (int, Direction, bool, string, time.Time, error) {
+	return genericQuerySchemaRecord(ctx, db, d, table)
+}
+
+func (d *postgresDriver) InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error {
+	return genericInsertSchemaHistory(ctx, db, d, table, version, direction, dirty, checksum)
+}
+
+func (d *postgresDriver) QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (string, error) {
+	return genericQueryChecksum(ctx, db, d, table, version)
+}
+
+func (d *postgresDriver) QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (string, time.Time, error) {
+	return genericQueryStepRecord(ctx, db, d, table, version)
+}
+
+func (d *postgresDriver) UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error {
+	return genericUpdateChecksum(ctx, db, d, table, version, checksum)
+}
+
+func (d *postgresDriver) SupportsTx() bool {
+	return true
+}
+
+func (d *postgresDriver) SplitStatements(content string) ([]string, error) {
+	return splitSQLStatements(content)
+}
+
+func (d *postgresDriver) Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error {
+	if tx != nil {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *postgresDriver) LockSchema(ctx context.Context, db *sql.DB, table string) (func(context.Context) error, error) {
+	if _, err := db.ExecContext(ctx, postgresLockQuery, table); err != nil {
+		return nil, err
+	}
+	return func(unlockCtx context.Context) error {
+		_, err := db.ExecContext(unlockCtx, postgresUnlockQuery, table)
+		return err
+	}, nil
+}
@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const sqliteCreateSchemaTableFormat = "CREATE TABLE IF NOT EXISTS %s (`id` INTEGER PRIMARY KEY AUTOINCREMENT, `version` INTEGER NOT NULL DEFAULT 0, `direction` TEXT NOT NULL DEFAULT 'up', `dirty` INTEGER NOT NULL DEFAULT 0, `checksum` TEXT NOT NULL DEFAULT '', `applied_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP);"
+
+// sqliteDriver 针对 SQLite，单连接场景下迁移天然串行，LockSchema 不需要做任何事
+type sqliteDriver struct{}
+
+func (d *sqliteDriver) Name() string {
+	return "sqlite"
+}
+
+func (d *sqliteDriver) QuoteIdent(ident string) string {
+	return "`" + ident + "`"
+}
+
+func (d *sqliteDriver) Placeholder(int) string {
+	return "?"
+}
+
+func (d *sqliteDriver) CreateSchemaTable(ctx context.Context, db *sql.DB, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(sqliteCreateSchemaTableFormat, table))
+	return err
+}
+
+func (d *sqliteDriver) InsertDefaultSchemaRecord(ctx context.Context, db *sql.DB, table string) error {
+	return genericInsertDefaultSchemaRecord(ctx, db, d, table)
+}
+
+func (d *sqliteDriver) QuerySchemaRecord(ctx context.Context, db *sql.DB, table string) (int, Direction, bool, string, time.Time, error) {
+	return genericQuerySchemaRecord(ctx, db, d, table)
+}
+
+func (d *sqliteDriver) InsertSchemaHistory(ctx context.Context, db *sql.DB, table string, version int, direction Direction, dirty bool, checksum string) error {
+	return genericInsertSchemaHistory(ctx, db, d, table, version, direction, dirty, checksum)
+}
+
+func (d *sqliteDriver) QueryChecksum(ctx context.Context, db *sql.DB, table string, version int) (string, error) {
+	return genericQueryChecksum(ctx, db, d, table, version)
+}
+
+func (d *sqliteDriver) QueryStepRecord(ctx context.Context, db *sql.DB, table string, version int) (string, time.Time, error) {
+	return genericQueryStepRecord(ctx, db, d, table, version)
+}
+
+func (d *sqliteDriver) UpdateChecksum(ctx context.Context, db *sql.DB, table string, version int, checksum string) error {
+	return genericUpdateChecksum(ctx, db, d, table, version, checksum)
+}
+
+func (d *sqliteDriver) SupportsTx() bool {
+	return true
+}
+
+func (d *sqliteDriver) SplitStatements(content string) ([]string, error) {
+	return splitSQLStatements(content)
+}
+
+func (d *sqliteDriver) Exec(ctx context.Context, tx *sql.Tx, db *sql.DB, query string) error {
+	if tx != nil {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
+
+func (d *sqliteDriver) LockSchema(ctx context.Context, db *sql.DB, table string) (func(context.Context) error, error) {
+	return func(context.Context) error { return nil }, nil
+}
@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// WithSchemaFS 指定迁移文件所在的 fs.FS，典型用法是配合 go:embed 将迁移文件打进二进制，
+// 例如 WithSchemaFS(embeddedMigrations)。fsys 应已经是迁移文件所在的目录本身
+// （若迁移文件在子目录下，调用方可自行用 fs.Sub 先裁剪一层），设置后 schemaDir 不再生效，
+// 且不再具备缺失 migrate.txt 时自动创建的行为（fs.FS 本身只读）。
+func WithSchemaFS(fsys fs.FS) Option {
+	return func(m *migrate) {
+		m.schemaFS = fsys
+	}
+}
+
+// fileSystem 返回本次读取迁移文件应使用的 fs.FS，未通过 WithSchemaFS 指定时
+// 默认使用 os.DirFS(schemaDir)，与历史版本行为保持一致
+func (m *migrate) fileSystem() fs.FS {
+	if m.schemaFS != nil {
+		return m.schemaFS
+	}
+	return os.DirFS(m.schemaDir)
+}
+
+// ValidateSchemaFS 校验 fsys 中 schemaFile 列出的每一个 .sql 文件都确实存在，
+// 用于在程序启动时尽早发现 go:embed 打包的迁移文件与 migrate.txt 不一致的问题，
+// 而不是等到真正执行迁移时才报错。go 方法类型的条目不在该 fs.FS 中，会被跳过。
+func ValidateSchemaFS(fsys fs.FS, schemaFile string) error {
+	file, err := fsys.Open(schemaFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	items, err := readSchemaItems(file)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if path.Ext(item) != sqlFileExt {
+			continue
+		}
+		if _, err = fs.Stat(fsys, item); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf(errFileNameNotExistFormat, item)
+			}
+			return err
+		}
+	}
+	return nil
+}
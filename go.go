@@ -2,35 +2,83 @@ package migrate
 
 import (
 	"context"
+	"database/sql"
 	"reflect"
 )
 
 // GoHandler 存储具体 go 反射处理程序
 type GoHandler struct {
-	index    int
-	function reflect.Value
-}
+	index     int
+	name      string
+	function  reflect.Value
+	direction Direction
+	checksum  string
+
+	db     *sql.DB // 方法声明了 *sql.Tx 参数且驱动支持事务时，用于开启事务
+	driver Driver  // 用于判断当前数据库是否支持事务
 
-type GoFunc func(ctx context.Context) error
+	container map[reflect.Type]reflect.Value // 依赖注入容器，解析 ctx/*sql.Tx 之外的参数
+}
 
 func (g *GoHandler) GetIndex() int {
 	return g.index
 }
 
+func (g *GoHandler) GetDirection() Direction {
+	return g.direction
+}
+
+func (g *GoHandler) GetChecksum() string {
+	return g.checksum
+}
+
+func (g *GoHandler) GetDescription() string {
+	return g.name
+}
+
+// Exec 按方法签名解析参数后调用：第一个参数固定为 ctx，若方法声明了 *sql.Tx 参数且
+// 当前驱动支持事务，则自动开启一个事务并在成功/失败时提交/回滚，使该步骤与 sql 步骤
+// 具备相同的原子性；其余参数从依赖注入容器中按类型解析
 func (g *GoHandler) Exec(ctx context.Context) error {
-	values := g.function.Call([]reflect.Value{reflect.ValueOf(ctx)})
-	if len(values) == 0 {
-		return nil
+	funcType := g.function.Type()
+
+	var tx *sql.Tx
+	needsTx := methodNeedsTx(funcType)
+	if needsTx && g.driver != nil && g.driver.SupportsTx() {
+		var err error
+		tx, err = g.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	args := resolveParams(funcType, ctx, tx, g.container)
+	values := g.function.Call(args)
+
+	var execErr error
+	if len(values) > 0 && !values[0].IsNil() {
+		execErr = values[0].Interface().(error)
+	}
+
+	if tx == nil {
+		return execErr
 	}
-	if values[0].IsNil() {
-		return nil
+	if execErr != nil {
+		_ = tx.Rollback()
+		return execErr
 	}
-	return values[0].Interface().(error)
+	return tx.Commit()
 }
 
-func newGoHandler(index int, function reflect.Value) Handler {
+func newGoHandler(index int, name string, function reflect.Value, direction Direction, checksum string, db *sql.DB, driver Driver, container map[reflect.Type]reflect.Value) Handler {
 	return &GoHandler{
-		index:    index,
-		function: function,
+		index:     index,
+		name:      name,
+		function:  function,
+		direction: direction,
+		checksum:  checksum,
+		db:        db,
+		driver:    driver,
+		container: container,
 	}
 }
@@ -0,0 +1,24 @@
+package migrate
+
+// Logger 是 dryRun 模式下用于记录预览信息的日志接口，*log.Logger 已经满足该接口，
+// 调用方也可以实现自己的 Logger 接入到已有的日志系统中
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+const dryRunStepFormat = "[dry-run] would execute %s step #%d: %s"
+
+// WithDryRun 设置为 true 时，run/runRollback 只会通过 Logger 记录将要执行的步骤，
+// 不会真正调用 handler.Exec，也不会写入概要表，便于在 CI 或管理工具中预览变更
+func WithDryRun(dryRun bool) Option {
+	return func(m *migrate) {
+		m.dryRun = dryRun
+	}
+}
+
+// WithLogger 替换 dryRun 模式下使用的 Logger，默认使用 log.Default()
+func WithLogger(logger Logger) Option {
+	return func(m *migrate) {
+		m.logger = logger
+	}
+}
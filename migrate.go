@@ -7,10 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 )
 
 /*
@@ -30,30 +34,23 @@ const (
 	errFileNameIsDuplicateFormat = "file name %s is duplicate"
 	errFileIsSameAsFuncFormat    = "file %s is same as func"
 
-	errFileNameNotExistFormat = "file name %s not exist"
-	errFuncNameNotExistFormat = "func name %s not exist"
+	errFileNameNotExistFormat   = "file name %s not exist"
+	errFuncNameNotExistFormat   = "func name %s not exist"
+	errDownScriptNotExistFormat = "down script for %s not exist"
 
 	errFindDirtyIndexFormat           = "find dirty version %d"
 	errSchemaVersionLargeRecordFormat = "schema version %d large than current record"
 
 	errFileTypeNotSupportedFormat = "file type %s not supported"
 
-	errFuncFormatNotCorrectFormat = "func format %s not correct, should be func(ctx context.Context) error"
-)
+	errFuncFormatNotCorrectFormat = "func format %s not correct, should be func(ctx context.Context, ...) error"
+	errFuncParamUnresolvedFormat  = "func %s has unresolvable param types: %s, register them via Migrate.Provide first"
 
-const (
-	insertDefaultSchema = "INSERT INTO %s (`version`, `dirty`) VALUES (0, 0)"
+	errRollbackTargetInvalidFormat = "rollback target version %d invalid, current version is %d"
+	errRollbackTargetDirtyFormat   = "rollback target version %d is dirty"
 )
 
-const (
-	createSchemaTableFormat = "CREATE TABLE IF NOT EXISTS %s (`version` int NOT NULL DEFAULT 0, `dirty` tinyint(1) NOT NULL DEFAULT 0) ENGINE=InnoDB;"
-
-	querySchemaRecordFormat = "SELECT `version`, `dirty` FROM %s LIMIT 1"
-
-	updateSchemaQuery = "UPDATE %s SET `version` = ?"
-
-	updateDirtyQuery = "UPDATE %s SET `version` = ?, `dirty` = ?"
-)
+const errChecksumMismatchFormat = "checksum mismatch for item %s at version %d, it may have been modified after being applied"
 
 const (
 	sqlFileExt = ".sql"
@@ -64,25 +61,65 @@ const (
 	fileJoinFormat = "%s/%s"
 )
 
+// downFuncSuffix 是 go 迁移方法的回滚方法后缀约定，例如 Exe1 的回滚方法为 Exe1Down
+const downFuncSuffix = "Down"
+
 const (
 	defaultSchemaTableName = "schema_migrations"
 	defaultSchemaDir       = "./migrations"
 	defaultSchemaFile      = "migrate.txt"
 )
 
+// Direction 标识处理单元的执行方向
+type Direction string
+
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// Handler 是一个可执行的处理单元，sqlHandler 和 GoHandler 均实现该接口
+type Handler interface {
+	GetIndex() int
+	GetDirection() Direction
+	// GetChecksum 返回该处理单元所执行内容的校验和，用于篡改检测
+	GetChecksum() string
+	// GetDescription 返回该处理单元的可读描述（sql 语句片段或 go 方法名），
+	// 供 WithDryRun 模式下的日志输出使用
+	GetDescription() string
+	Exec(ctx context.Context) error
+}
+
 type Migrate interface {
 	AddHandlers(handlers ...Handler)
 
 	Run(ctx context.Context) error
+	// RollbackTo 将已执行的迁移依次回滚至 targetVersion
+	RollbackTo(ctx context.Context, targetVersion int) error
+	// RollbackSteps 从当前版本往回回滚 steps 步
+	RollbackSteps(ctx context.Context, steps int) error
+	// Repair 按当前概要文件与 go 方法重新计算已执行条目的校验和并写回概要表，
+	// 用于操作者确认某次历史改动是有意为之之后，清除 WithChecksumMode 产生的告警/报错
+	Repair(ctx context.Context) error
+	// Status 返回概要文件中每个条目的当前状态，用于在不执行迁移的前提下查看进度
+	Status(ctx context.Context) ([]StepStatus, error)
+	// Plan 按 Run 的前 5 步构建将要执行的 handlers，但不实际执行，用于预览
+	Plan(ctx context.Context) ([]Handler, error)
 	ApplyObjects(objects ...interface{})
+	// Provide 向依赖注入容器注册实例，GoHandler 执行时按类型解析方法的额外参数
+	Provide(values ...interface{})
 }
 
 func NewMigrate(db *sql.DB, options ...Option) Migrate {
 	m := &migrate{
-		db:          db,
-		schemaDir:   defaultSchemaDir,
-		schemaFile:  defaultSchemaFile,
-		schemaTable: defaultSchemaTableName,
+		db:           db,
+		driver:       detectDriver(db),
+		schemaDir:    defaultSchemaDir,
+		schemaFile:   defaultSchemaFile,
+		schemaTable:  defaultSchemaTableName,
+		checksumMode: ChecksumModeOff,
+		logger:       log.Default(),
+		container:    make(map[reflect.Type]reflect.Value),
 	}
 	for _, option := range options {
 		option(m)
@@ -93,11 +130,20 @@ func NewMigrate(db *sql.DB, options ...Option) Migrate {
 type migrate struct {
 	mutex sync.Mutex
 
-	db *sql.DB // db 连接
+	db     *sql.DB // db 连接
+	driver Driver  // 数据库驱动，默认根据 db 的底层驱动类型自动识别，可通过 WithDriver 覆盖
 
 	schemaDir   string // 概要目录
 	schemaFile  string // 概要文件，用于记录处理单元顺序，默认存在于概要目录下
 	schemaTable string // 概要表
+	schemaFS    fs.FS  // 迁移文件所在的 fs.FS，未通过 WithSchemaFS 指定时为 nil，此时使用 os.DirFS(schemaDir)
+
+	checksumMode ChecksumMode // 已执行迁移的校验和校验模式，默认 ChecksumModeOff
+
+	dryRun bool   // 为 true 时只记录日志，不实际执行 handler 也不写入概要表
+	logger Logger // dryRun 模式下用于记录预览信息的日志接口，默认 log.Default()
+
+	container map[reflect.Type]reflect.Value // 依赖注入容器，按类型存放 Provide 注册的实例
 
 	applyObjects []interface{}
 
@@ -116,41 +162,76 @@ func (m *migrate) ApplyObjects(objects ...interface{}) {
 	m.applyObjects = append(m.applyObjects, objects...)
 }
 
+// Provide 按实例的动态类型注册到依赖注入容器，同一类型的后续调用会覆盖之前的实例
+func (m *migrate) Provide(values ...interface{}) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, value := range values {
+		m.container[reflect.TypeOf(value)] = reflect.ValueOf(value)
+	}
+}
+
 func (m *migrate) Run(ctx context.Context) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
+	handlers, err := m.buildHandlers(ctx)
+	if err != nil {
+		return err
+	}
+	// 6.依次执行 handlers
+	m.handlers = append(m.handlers, handlers...)
+	return m.run(ctx)
+}
+
+// Plan 按 buildHandlers（即 Run 的前 5 步）构建将要执行的 handlers 但不执行，用于预览
+func (m *migrate) Plan(ctx context.Context) ([]Handler, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.buildHandlers(ctx)
+}
+
+// buildHandlers 对应 Run 的步骤 1-5：读取概要文件与执行记录，校验一致性，
+// 并从已执行的位置开始构建接下来需要执行的 handlers，但不执行也不修改 m.handlers
+func (m *migrate) buildHandlers(ctx context.Context) ([]Handler, error) {
 	// 1.读取概要文件，中间不能有空行，校验执行名称非重复
 	items, err := m.getSchemaItems()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// 2.获取需要执行函数的结构体，校验函数名称非重复
 	methodMap, err := m.getMethods()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// 3.获取需要执行的文件，校验文件名称非重复
 	fileNameMap, err := m.getFileNames()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for fileName := range fileNameMap {
 		if _, ok := methodMap[fileName]; ok {
-			return fmt.Errorf(fmt.Sprintf(errFileIsSameAsFuncFormat, fileName))
+			return nil, fmt.Errorf(fmt.Sprintf(errFileIsSameAsFuncFormat, fileName))
 		}
 	}
 	// 4.读取概要表，获取执行记录
-	record, err := m.getSchemaRecord()
+	record, err := m.getSchemaRecord(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if record.dirty {
-		return fmt.Errorf(fmt.Sprintf(errFindDirtyIndexFormat, record.version))
+		return nil, fmt.Errorf(fmt.Sprintf(errFindDirtyIndexFormat, record.version))
 	}
 	if len(items) < record.version {
-		return fmt.Errorf(fmt.Sprintf(errSchemaVersionLargeRecordFormat, record.version))
+		return nil, fmt.Errorf(fmt.Sprintf(errSchemaVersionLargeRecordFormat, record.version))
+	}
+	// 4.5 校验已执行条目的内容未被篡改
+	if m.checksumMode != ChecksumModeOff {
+		if err = m.verifyChecksums(ctx, items, record.version, methodMap); err != nil {
+			return nil, err
+		}
 	}
 	// 5.获取执行记录位置，从该位置开始构建 handlers
+	var handlers []Handler
 	for i := record.version; i < len(items); i++ {
 		item := items[i]
 
@@ -158,52 +239,64 @@ func (m *migrate) Run(ctx context.Context) error {
 		switch ext {
 		case sqlFileExt:
 			if _, ok := fileNameMap[item]; !ok {
-				return fmt.Errorf(errFileNameNotExistFormat, item)
+				return nil, fmt.Errorf(errFileNameNotExistFormat, item)
 			}
-			sqlContent, err := getFileContent(fmt.Sprintf(fileJoinFormat, m.schemaDir, item))
+			upContent, _, err := getSQLDirectionContents(m.fileSystem(), item)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			m.handlers = append(m.handlers, newSQLHandler(m.db, i+1, sqlContent))
+			handlers = append(handlers, newSQLHandler(m.db, m.driver, i+1, upContent, DirectionUp, sqlChecksum(upContent)))
 		case funcExt:
 			if _, ok := methodMap[item]; !ok {
-				return fmt.Errorf(errFuncNameNotExistFormat, item)
+				return nil, fmt.Errorf(errFuncNameNotExistFormat, item)
 			}
 			method := methodMap[item]
-			// 校验方法的参数，格式必须满足 func(context.Context) error
-			err = checkMethodFormat(&method)
+			// 校验方法参数，第一个参数必须是 context.Context，返回值必须是 error，
+			// 其余参数必须能从依赖注入容器中按类型解析（*sql.Tx 除外，由 GoHandler 自动注入）
+			err = checkMethodFormat(&method, m.container)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			m.handlers = append(m.handlers, newGoHandler(i+1, method.value))
+			handlers = append(handlers, newGoHandler(i+1, method.name, method.value, DirectionUp, funcChecksum(method), m.db, m.driver, m.container))
 		default:
 			// 后续可自行扩展其他类型
-			return fmt.Errorf(errFileTypeNotSupportedFormat, item)
+			return nil, fmt.Errorf(errFileTypeNotSupportedFormat, item)
 		}
 	}
-	// 6.依次执行 handlers
-	return m.run(ctx)
+	return handlers, nil
 }
 
 func (m *migrate) getSchemaItems() ([]string, error) {
-	schemaFileName := fmt.Sprintf(fileJoinFormat, m.schemaDir, m.schemaFile)
-	schemaFile, err := os.Open(schemaFileName)
-	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			err = os.MkdirAll(path.Dir(schemaFileName), os.ModePerm)
-			if err != nil {
+	if m.schemaFS == nil {
+		// 默认使用本地目录时，概要文件缺失需自动创建，保持向后兼容；
+		// 而通过 WithSchemaFS 指定的 fs.FS（如 go:embed）是只读的，不做这一步
+		schemaFileName := fmt.Sprintf(fileJoinFormat, m.schemaDir, m.schemaFile)
+		if _, err := os.Stat(schemaFileName); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
 				return nil, err
 			}
-			schemaFile, err = os.Create(schemaFileName)
+			if err = os.MkdirAll(m.schemaDir, os.ModePerm); err != nil {
+				return nil, err
+			}
+			file, err := os.Create(schemaFileName)
 			if err != nil {
 				return nil, err
 			}
-		} else {
-			return nil, err
+			_ = file.Close()
 		}
 	}
 
-	scanner := bufio.NewScanner(schemaFile)
+	schemaFile, err := m.fileSystem().Open(m.schemaFile)
+	if err != nil {
+		return nil, err
+	}
+	defer schemaFile.Close()
+	return readSchemaItems(schemaFile)
+}
+
+// readSchemaItems 从概要文件读取处理单元顺序，中间不能有空行，执行名称不能重复
+func readSchemaItems(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
 	itemMap := make(map[string]struct{})
 	var (
 		index       int
@@ -228,30 +321,30 @@ func (m *migrate) getSchemaItems() ([]string, error) {
 	return schemaItems, nil
 }
 
-func (m *migrate) getSchemaRecord() (*schema, error) {
-	err := m.initSchemaTable()
+func (m *migrate) getSchemaRecord(ctx context.Context) (*schema, error) {
+	err := m.initSchemaTable(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var sche schema
-	err = m.db.QueryRow(fmt.Sprintf(querySchemaRecordFormat, m.schemaTable)).
-		Scan(&sche.version, &sche.dirty)
+	sche.version, sche.direction, sche.dirty, sche.checksum, sche.appliedAt, err =
+		m.driver.QuerySchemaRecord(ctx, m.db, m.schemaTable)
 	if err == nil {
 		return &sche, nil
 	}
 	if errors.Is(err, sql.ErrNoRows) {
-		_, err = m.db.Exec(fmt.Sprintf(insertDefaultSchema, m.schemaTable))
+		err = m.driver.InsertDefaultSchemaRecord(ctx, m.db, m.schemaTable)
 		if err != nil {
 			return nil, err
 		}
+		sche.direction = DirectionUp
 	}
 	return &sche, err
 }
 
-func (m *migrate) initSchemaTable() error {
-	_, err := m.db.Exec(fmt.Sprintf(createSchemaTableFormat, m.schemaTable))
-	return err
+func (m *migrate) initSchemaTable(ctx context.Context) error {
+	return m.driver.CreateSchemaTable(ctx, m.db, m.schemaTable)
 }
 
 func (m *migrate) getMethods() (map[string]method, error) {
@@ -274,7 +367,7 @@ func (m *migrate) getMethods() (map[string]method, error) {
 }
 
 func (m *migrate) getFileNames() (map[string]struct{}, error) {
-	entries, err := os.ReadDir(m.schemaDir)
+	entries, err := fs.ReadDir(m.fileSystem(), ".")
 	if err != nil {
 		return nil, err
 	}
@@ -293,20 +386,31 @@ func (m *migrate) getFileNames() (map[string]struct{}, error) {
 }
 
 func (m *migrate) run(ctx context.Context) error {
+	unlock, err := m.driver.LockSchema(ctx, m.db, m.schemaTable)
+	if err != nil {
+		return err
+	}
+	defer unlock(ctx)
+
 	for _, handler := range m.handlers {
+		if m.dryRun {
+			m.logger.Printf(dryRunStepFormat, handler.GetDirection(), handler.GetIndex(), handler.GetDescription())
+			continue
+		}
+
 		err := handler.Exec(ctx)
 		if err != nil {
-			// 发生错误时，记录 dirty 到 schema 表
-			_, innerErr := m.db.Exec(fmt.Sprintf(updateDirtyQuery, m.schemaTable),
-				handler.GetIndex(), 1)
+			// 发生错误时，插入一条 dirty 记录
+			innerErr := m.driver.InsertSchemaHistory(ctx, m.db, m.schemaTable,
+				handler.GetIndex(), handler.GetDirection(), true, handler.GetChecksum())
 			if innerErr != nil {
 				return innerErr
 			}
 			return err
 		}
-		// 成功时更新 version 字段
-		_, err = m.db.Exec(fmt.Sprintf(updateSchemaQuery, m.schemaTable),
-			handler.GetIndex())
+		// 成功时插入一条历史记录，推进 version
+		err = m.driver.InsertSchemaHistory(ctx, m.db, m.schemaTable,
+			handler.GetIndex(), handler.GetDirection(), false, handler.GetChecksum())
 		if err != nil {
 			return err
 		}
@@ -314,44 +418,138 @@ func (m *migrate) run(ctx context.Context) error {
 	return nil
 }
 
-func getFileContent(fileName string) (string, error) {
-	file, err := os.Open(fileName)
+// RollbackTo 将已执行的迁移依次回滚至 targetVersion，回滚顺序与执行顺序相反
+func (m *migrate) RollbackTo(ctx context.Context, targetVersion int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.rollback(ctx, targetVersion)
+}
+
+// RollbackSteps 从当前版本往回回滚 steps 步，等价于 RollbackTo(ctx, currentVersion-steps)
+func (m *migrate) RollbackSteps(ctx context.Context, steps int) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	record, err := m.getSchemaRecord(ctx)
 	if err != nil {
-		return "", err
+		return err
 	}
-	defer file.Close()
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
+	target := record.version - steps
+	if target < 0 {
+		target = 0
 	}
-	return string(content), nil
+	return m.rollback(ctx, target)
 }
 
-func checkMethodFormat(mh *method) error {
-	if mh.value.Kind() != reflect.Func {
-		return ErrParamIsNotFunc
+// rollback 构建从当前版本到 targetVersion 的反向 handlers 并依次执行
+func (m *migrate) rollback(ctx context.Context, targetVersion int) error {
+	// 1.读取概要文件与执行记录，校验执行名称非重复
+	items, err := m.getSchemaItems()
+	if err != nil {
+		return err
 	}
+	methodMap, err := m.getMethods()
+	if err != nil {
+		return err
+	}
+	record, err := m.getSchemaRecord(ctx)
+	if err != nil {
+		return err
+	}
+	if targetVersion < 0 || targetVersion > record.version {
+		return fmt.Errorf(errRollbackTargetInvalidFormat, targetVersion, record.version)
+	}
+	// dirty 时回滚范围必须包含当前这一笔失败的记录（targetVersion 小于当前 version），
+	// 回滚成功后写入的非 dirty 记录借此清除 dirty 状态
+	if record.dirty && targetVersion >= record.version {
+		return fmt.Errorf(errRollbackTargetDirtyFormat, targetVersion)
+	}
+	// 2.从当前 version 开始，依次构建反向 handlers
+	var handlers []Handler
+	for i := record.version; i > targetVersion; i-- {
+		item := items[i-1]
 
-	if mh.value.Type().NumIn() != 1 || mh.value.Type().NumOut() != 1 {
-		return fmt.Errorf(errFuncFormatNotCorrectFormat, mh.name)
+		ext := path.Ext(item)
+		switch ext {
+		case sqlFileExt:
+			_, downContent, err := getSQLDirectionContents(m.fileSystem(), item)
+			if err != nil {
+				return err
+			}
+			// 与 go 方法缺少 <Name>Down 时一致，没有 down 脚本视为错误而非静默跳过——
+			// 否则会把一次不可逆的 sql 迁移当作回滚成功记录下来
+			if strings.TrimSpace(downContent) == "" {
+				return fmt.Errorf(errDownScriptNotExistFormat, item)
+			}
+			handlers = append(handlers, newSQLHandler(m.db, m.driver, i, downContent, DirectionDown, sqlChecksum(downContent)))
+		case funcExt:
+			downName := item + downFuncSuffix
+			downMethod, ok := methodMap[downName]
+			if !ok {
+				return fmt.Errorf(errFuncNameNotExistFormat, downName)
+			}
+			if err = checkMethodFormat(&downMethod, m.container); err != nil {
+				return err
+			}
+			handlers = append(handlers, newGoHandler(i, downMethod.name, downMethod.value, DirectionDown, funcChecksum(downMethod), m.db, m.driver, m.container))
+		default:
+			return fmt.Errorf(errFileTypeNotSupportedFormat, item)
+		}
+	}
+	// 3.依次执行反向 handlers
+	return m.runRollback(ctx, handlers)
+}
+
+func (m *migrate) runRollback(ctx context.Context, handlers []Handler) error {
+	unlock, err := m.driver.LockSchema(ctx, m.db, m.schemaTable)
+	if err != nil {
+		return err
 	}
-	paramInType := mh.value.Type().In(0)
-	paramOutType := mh.value.Type().Out(0)
-	if paramInType != reflect.TypeOf((*context.Context)(nil)).Elem() ||
-		paramOutType != reflect.TypeOf((*error)(nil)).Elem() {
-		return fmt.Errorf(errFuncFormatNotCorrectFormat, mh.name)
+	defer unlock(ctx)
+
+	for _, handler := range handlers {
+		if m.dryRun {
+			m.logger.Printf(dryRunStepFormat, handler.GetDirection(), handler.GetIndex(), handler.GetDescription())
+			continue
+		}
+
+		err := handler.Exec(ctx)
+		newVersion := handler.GetIndex() - 1
+		if err != nil {
+			innerErr := m.driver.InsertSchemaHistory(ctx, m.db, m.schemaTable,
+				handler.GetIndex(), handler.GetDirection(), true, handler.GetChecksum())
+			if innerErr != nil {
+				return innerErr
+			}
+			return err
+		}
+		err = m.driver.InsertSchemaHistory(ctx, m.db, m.schemaTable,
+			newVersion, handler.GetDirection(), false, handler.GetChecksum())
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func getFileContent(fsys fs.FS, fileName string) (string, error) {
+	content, err := fs.ReadFile(fsys, fileName)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
 type method struct {
 	name  string
 	value reflect.Value
 }
 
 type schema struct {
-	version int
-	dirty   bool
+	version   int
+	direction Direction
+	dirty     bool
+	checksum  string
+	appliedAt time.Time
 }
 
 type Option func(m *migrate)
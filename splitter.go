@@ -0,0 +1,176 @@
+package migrate
+
+import "strings"
+
+// noTransactionDirective 是迁移文件中用于声明该文件不应在事务内执行的注释指令，
+// 常见于 Postgres 的 CREATE INDEX CONCURRENTLY 等无法运行在事务中的 DDL
+const noTransactionDirective = "-- migrate:no-transaction"
+
+const defaultStatementDelimiter = ";"
+
+// hasNoTransactionDirective 判断文件体中是否包含 noTransactionDirective，并返回去除该行后的内容
+func hasNoTransactionDirective(content string) (bool, string) {
+	lines := strings.Split(content, "\n")
+	found := false
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) == noTransactionDirective {
+			found = true
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return found, strings.Join(kept, "\n")
+}
+
+// splitSQLStatements 将一个迁移文件体拆分为可逐条执行的语句。
+// 识别字符串字面量（'...'/"..."，支持反斜杠转义与双写转义）、"--" 行注释、
+// "/* */" 块注释、Postgres 风格的 "$$...$$" 美元引用块，以及 MySQL 存储过程中常见的
+// "DELIMITER xxx" 覆写；这些区域内出现的语句分隔符不会被当作语句边界。
+func splitSQLStatements(content string) ([]string, error) {
+	delimiter := defaultStatementDelimiter
+	var statements []string
+	var current strings.Builder
+
+	n := len(content)
+	i := 0
+	atLineStart := true
+	for i < n {
+		if atLineStart {
+			if newDelimiter, consumed, ok := matchDelimiterDirective(content[i:]); ok {
+				delimiter = newDelimiter
+				i += consumed
+				atLineStart = true
+				continue
+			}
+		}
+		atLineStart = false
+
+		c := content[i]
+		switch {
+		case c == '-' && i+1 < n && content[i+1] == '-':
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				current.WriteString(content[i:])
+				i = n
+			} else {
+				current.WriteString(content[i : i+end+1])
+				i += end + 1
+				atLineStart = true
+			}
+		case c == '/' && i+1 < n && content[i+1] == '*':
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				current.WriteString(content[i:])
+				i = n
+			} else {
+				stop := i + 2 + end + 2
+				current.WriteString(content[i:stop])
+				i = stop
+			}
+		case c == '\'' || c == '"':
+			stop := scanQuoted(content, i, c)
+			current.WriteString(content[i:stop])
+			i = stop
+		case strings.HasPrefix(content[i:], delimiter):
+			if stmt := strings.TrimSpace(current.String()); stmt != "" {
+				statements = append(statements, stmt)
+			}
+			current.Reset()
+			i += len(delimiter)
+			atLineStart = true
+		case c == '$':
+			if stop, ok := scanDollarQuoted(content, i); ok {
+				current.WriteString(content[i:stop])
+				i = stop
+				continue
+			}
+			current.WriteByte(c)
+			i++
+		case c == '\n':
+			current.WriteByte(c)
+			i++
+			atLineStart = true
+		default:
+			current.WriteByte(c)
+			i++
+		}
+	}
+	if stmt := strings.TrimSpace(current.String()); stmt != "" {
+		statements = append(statements, stmt)
+	}
+	return statements, nil
+}
+
+// scanQuoted 返回从 i 处的引号开始的字符串字面量结束位置（含右引号）
+func scanQuoted(content string, i int, quote byte) int {
+	n := len(content)
+	j := i + 1
+	for j < n {
+		switch content[j] {
+		case '\\':
+			j += 2
+		case quote:
+			if j+1 < n && content[j+1] == quote {
+				j += 2
+				continue
+			}
+			return j + 1
+		default:
+			j++
+		}
+	}
+	return n
+}
+
+// scanDollarQuoted 识别 Postgres 风格的 "$tag$...$tag$" 美元引用块（tag 可为空，即 "$$...$$"），
+// 返回结束位置（含结尾 "$tag$"）。content[i] 必须是 '$'
+func scanDollarQuoted(content string, i int) (int, bool) {
+	n := len(content)
+	j := i + 1
+	for j < n && (isAlnum(content[j]) || content[j] == '_') {
+		j++
+	}
+	if j >= n || content[j] != '$' {
+		return 0, false
+	}
+	open := content[i : j+1]
+	closeIdx := strings.Index(content[j+1:], open)
+	if closeIdx < 0 {
+		return n, true
+	}
+	return j + 1 + closeIdx + len(open), true
+}
+
+func isAlnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// matchDelimiterDirective 识别行首的 "DELIMITER <sep>" 指令（大小写不敏感），
+// 返回新的分隔符以及应跳过的字节数（含该指令所在整行及换行符）
+func matchDelimiterDirective(rest string) (delimiter string, consumed int, ok bool) {
+	trimmed := strings.TrimLeft(rest, " \t")
+	const prefix = "DELIMITER"
+	if len(trimmed) < len(prefix) || !strings.EqualFold(trimmed[:len(prefix)], prefix) {
+		return "", 0, false
+	}
+	afterPrefix := trimmed[len(prefix):]
+	if afterPrefix == "" || (afterPrefix[0] != ' ' && afterPrefix[0] != '\t') {
+		return "", 0, false
+	}
+
+	lineEnd := strings.IndexByte(rest, '\n')
+	var line string
+	if lineEnd < 0 {
+		line = rest
+		consumed = len(rest)
+	} else {
+		line = rest[:lineEnd]
+		consumed = lineEnd + 1
+	}
+	newDelimiter := strings.TrimSpace(line[len(rest)-len(trimmed)+len(prefix):])
+	if newDelimiter == "" {
+		return "", 0, false
+	}
+	return newDelimiter, consumed, true
+}
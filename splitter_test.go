@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSQLStatementsDollarDelimitedProcedure(t *testing.T) {
+	// 覆盖 MySQL 存储过程常见写法：DELIMITER $$ ... END$$ DELIMITER ;
+	// 生效中的 "$$" 分隔符必须在美元引用块探测之前被识别为语句边界
+	content := "DELIMITER $$\n" +
+		"CREATE PROCEDURE proc1()\n" +
+		"BEGIN\n" +
+		"  SELECT 1;\n" +
+		"END$$\n" +
+		"DELIMITER ;\n" +
+		"SELECT 2;\n"
+
+	statements, err := splitSQLStatements(content)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE PROCEDURE proc1()\nBEGIN\n  SELECT 1;\nEND",
+		"SELECT 2",
+	}, statements)
+}
+
+func TestSplitSQLStatementsDollarQuotedBlock(t *testing.T) {
+	// 默认分隔符下，"$$...$$" 仍应按 Postgres 美元引用块处理，而非被当作分隔符
+	content := "CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  RETURN 1;\nEND\n$$ LANGUAGE plpgsql;\n"
+
+	statements, err := splitSQLStatements(content)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE FUNCTION f() RETURNS int AS $$\nBEGIN\n  RETURN 1;\nEND\n$$ LANGUAGE plpgsql",
+	}, statements)
+}
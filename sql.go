@@ -3,46 +3,172 @@ package migrate
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path"
+	"strings"
 )
 
 const (
 	ErrQueryWithIndexFormat = "%s; query err with index is %d"
+
+	errStatementFormat = "%s (statement #%d: %s)"
+)
+
+const statementSnippetMaxLen = 80
+
+const (
+	migrateUpMarker   = "-- +migrate Up"
+	migrateDownMarker = "-- +migrate Down"
 )
 
-func newSQLHandler(db *sql.DB, index int, query string) Handler {
+const downFileSuffix = "_down"
+
+func newSQLHandler(db *sql.DB, driver Driver, index int, query string, direction Direction, checksum string) Handler {
 	return &sqlHandler{
-		index: index,
-		query: query,
-		db:    db,
+		index:     index,
+		query:     query,
+		db:        db,
+		driver:    driver,
+		direction: direction,
+		checksum:  checksum,
 	}
 }
 
 // sqlHandler 包含具体 sql 语句
 type sqlHandler struct {
-	index int
-	query string
-	db    *sql.DB
+	index     int
+	query     string
+	db        *sql.DB
+	driver    Driver
+	direction Direction
+	checksum  string
 }
 
 func (s *sqlHandler) GetIndex() int {
 	return s.index
 }
 
+func (s *sqlHandler) GetDirection() Direction {
+	return s.direction
+}
+
+func (s *sqlHandler) GetChecksum() string {
+	return s.checksum
+}
+
+func (s *sqlHandler) GetDescription() string {
+	return statementSnippet(s.query)
+}
+
 func (s *sqlHandler) Exec(ctx context.Context) error {
 	if s.query == "" {
 		return nil
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	noTx, body := hasNoTransactionDirective(s.query)
+	statements, err := s.driver.SplitStatements(body)
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec(s.query)
+	if len(statements) == 0 {
+		return nil
+	}
+
+	// no-transaction 指令或驱动本身不支持事务时，逐条语句直接在 db 上执行，
+	// 失败时已经成功执行的语句不会被回滚，dirty 状态会记录在本次迁移步骤上
+	if noTx || !s.driver.SupportsTx() {
+		for i, stmt := range statements {
+			if err = s.driver.Exec(ctx, nil, s.db, stmt); err != nil {
+				return fmt.Errorf(ErrQueryWithIndexFormat, wrapStatementError(err, i, stmt), s.index)
+			}
+		}
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf(ErrQueryWithIndexFormat, err.Error(), s.index)
+		return err
+	}
+	for i, stmt := range statements {
+		if err = s.driver.Exec(ctx, tx, s.db, stmt); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf(ErrQueryWithIndexFormat, wrapStatementError(err, i, stmt), s.index)
+		}
 	}
 	_ = tx.Commit()
 	return nil
 }
+
+// wrapStatementError 在原始错误基础上附加语句序号与片段，方便定位具体是哪条语句执行失败
+func wrapStatementError(err error, index int, stmt string) string {
+	return fmt.Sprintf(errStatementFormat, err.Error(), index+1, statementSnippet(stmt))
+}
+
+func statementSnippet(stmt string) string {
+	snippet := strings.Join(strings.Fields(stmt), " ")
+	if len(snippet) > statementSnippetMaxLen {
+		return snippet[:statementSnippetMaxLen] + "..."
+	}
+	return snippet
+}
+
+// getSQLDirectionContents 解析出文件对应的 up/down sql 内容。
+// 优先识别文件体内的 "-- +migrate Up" / "-- +migrate Down" 区块标记，
+// 否则按照 1_up.sql/1_down.sql 的成对文件约定查找同目录下的 down 文件，
+// 若均不存在，则返回空的 down 内容，回滚到该步骤时会以 errDownScriptNotExistFormat 报错。
+func getSQLDirectionContents(fsys fs.FS, fileName string) (up string, down string, err error) {
+	content, err := getFileContent(fsys, fileName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if up, down, ok := splitMigrateSections(content); ok {
+		return up, down, nil
+	}
+
+	downFileName := toDownFileName(fileName)
+	downContent, err := getFileContent(fsys, downFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return content, "", nil
+		}
+		return "", "", err
+	}
+	return content, downContent, nil
+}
+
+// splitMigrateSections 按 migrateUpMarker/migrateDownMarker 拆分文件内容
+func splitMigrateSections(content string) (up string, down string, ok bool) {
+	upIdx := strings.Index(content, migrateUpMarker)
+	downIdx := strings.Index(content, migrateDownMarker)
+	if upIdx < 0 && downIdx < 0 {
+		return "", "", false
+	}
+	if upIdx >= 0 && downIdx >= 0 && upIdx < downIdx {
+		up = strings.TrimSpace(content[upIdx+len(migrateUpMarker) : downIdx])
+		down = strings.TrimSpace(content[downIdx+len(migrateDownMarker):])
+		return up, down, true
+	}
+	if upIdx >= 0 && downIdx >= 0 {
+		down = strings.TrimSpace(content[downIdx+len(migrateDownMarker) : upIdx])
+		up = strings.TrimSpace(content[upIdx+len(migrateUpMarker):])
+		return up, down, true
+	}
+	if upIdx >= 0 {
+		return strings.TrimSpace(content[upIdx+len(migrateUpMarker):]), "", true
+	}
+	return "", strings.TrimSpace(content[downIdx+len(migrateDownMarker):]), true
+}
+
+// toDownFileName 将 up 文件名转换为成对的 down 文件名，
+// 例如 1_up.sql -> 1_down.sql，1.sql -> 1_down.sql
+func toDownFileName(fileName string) string {
+	ext := path.Ext(fileName)
+	base := strings.TrimSuffix(fileName, ext)
+	if strings.HasSuffix(base, "_up") {
+		return strings.TrimSuffix(base, "_up") + downFileSuffix + ext
+	}
+	return base + downFileSuffix + ext
+}
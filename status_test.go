@@ -0,0 +1,49 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusReturnsPartialResultForMissingHistoryRow(t *testing.T) {
+	// 历史版本的 (version, dirty) 单行概要表升级而来时，version 1 没有独立的历史行，
+	// Driver.QueryStepRecord 返回 sql.ErrNoRows，Status 应继续报告 applied 而不是整体报错
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COLUMN_NAME FROM information_schema.COLUMNS")).
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).
+			AddRow("id").AddRow("version").AddRow("direction").
+			AddRow("dirty").AddRow("checksum").AddRow("applied_at"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `version`, `direction`, `dirty`, `checksum`, `applied_at` FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "direction", "dirty", "checksum", "applied_at"}).
+			AddRow(1, "up", false, "", time.Time{}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `checksum`, `applied_at` FROM schema_migrations")).
+		WithArgs(1).
+		WillReturnError(sql.ErrNoRows)
+
+	m := &migrate{
+		db:          db,
+		driver:      &mysqlDriver{},
+		schemaTable: "schema_migrations",
+		schemaFile:  "migrate.txt",
+		schemaFS:    fstest.MapFS{"migrate.txt": &fstest.MapFile{Data: []byte("1.sql\n")}},
+	}
+	statuses, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []StepStatus{{Index: 1, Item: "1.sql", Kind: "sql", State: StepApplied}}, statuses)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}